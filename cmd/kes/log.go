@@ -17,6 +17,9 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/minio/kes"
+	internallog "github.com/minio/kes/internal/log"
+	"github.com/minio/kes/internal/metrics"
+	"github.com/minio/kes/internal/share"
 	"github.com/minio/kes/internal/xterm"
 
 	ui "github.com/gizak/termui/v3"
@@ -27,6 +30,7 @@ const logCmdUsage = `Usage:
 
 Commands:
     trace                  Trace server log events.
+    tail                   Replay recent log events, then trace.
 
 Options:
     -h, --help             Show list of command-line options.
@@ -45,6 +49,8 @@ func log(args []string) {
 	switch args = cli.Args(); args[0] {
 	case "trace":
 		logTrace(args)
+	case "tail":
+		logTail(args)
 	default:
 		stdlog.Fatalf("Error: %q is not a kes log command. See 'kes log --help'", args[0])
 	}
@@ -60,6 +66,59 @@ Options:
                              --type=error
 
     --json                 Print log events as JSON.
+    --sink {file|syslog|gelf|elasticsearch}
+                           In addition to the terminal UI / STDOUT, forward
+                           every log event to the named structured sink.
+    --sink-url <url>       Sink-specific destination. A file path, a
+                           'host:port' or an HTTP(S) endpoint - depending
+                           on --sink.
+    --level {debug|info|warn|error}
+                           Minimum severity forwarded to --sink. (default: info)
+    --format {json|logfmt|cef}
+                           Wire format used by --sink. (default: json)
+    --dashboard            Replace the plain table UI with a multi-pane
+                           dashboard: a live filter bar, a scrollback ring
+                           buffer with pause/resume, a selected-event detail
+                           view and a stats pane with the request count and
+                           error rate since start, latency quantiles over
+                           --window and an error-rate spark line.
+    --ring-size <n>        Scrollback ring buffer size for --dashboard. (default: 10000)
+    --window <duration>    Width of the rolling stats window for --dashboard. (default: 60s)
+    --share                Serve the trace over an embedded, read-only HTTP
+                           server so teammates can follow along from a
+                           browser. No input is ever forwarded back to
+                           this CLI.
+    --share-addr <addr>    Address the share server listens on. (default: :7474)
+    --share-token <token>  Per-session access token. Generated and printed
+                           if not given.
+    --share-tls-cert <file>
+                           TLS certificate for the share server. Without
+                           this the trace and its access token are sent
+                           over plain HTTP/WS.
+    --share-tls-key <file> TLS private key for --share-tls-cert.
+    --share-relay-addr <addr>
+                           Instead of listening locally, dial this
+                           address and serve the share session over a
+                           yamux-multiplexed connection to a public
+                           relay - for viewers behind NAT.
+    --share-idle-timeout <duration>
+                           Disconnect a viewer after this much time with
+                           no activity on its connection. (default: 30m)
+    --metrics-addr <addr>  Expose derived Prometheus/OpenMetrics series at
+                           http://<addr>/metrics instead of - or alongside -
+                           the terminal UI: kes_requests_total,
+                           kes_request_duration_seconds, kes_errors_total
+                           and stream health gauges. High-cardinality
+                           identity/path label values beyond --metrics-top-k
+                           collapse into an "other" bucket. With a metrics
+                           sink configured, a dropped stream is reconnected
+                           with backoff instead of exiting, and counted in
+                           kes_stream_reconnects_total.
+    --metrics-top-k <n>    Distinct identity/path label values tracked
+                           before collapsing into "other". (default: 20)
+    --pushgateway-url <url>
+                           Push metrics to a Prometheus Pushgateway instead
+                           of serving --metrics-addr.
     -k, --insecure         Skip X.509 certificate validation during TLS handshake.
     -h, --help             Show list of command-line options.
 
@@ -68,8 +127,18 @@ a terminal it displays a table-view terminal UI that shows the stream of
 log events. Otherwise, or when --json is specified, the log events are
 written to standard output in JSON format.
 
+If --sink is specified the event stream is additionally forwarded, as
+structured events, to the given sink - on top of whatever is written to
+the terminal or STDOUT. A slow or unreachable sink never blocks tracing
+since sink writes are buffered and dropped under backpressure.
+
 Examples:
     $ kes log trace
+    $ kes log trace --sink=file --sink-url=/var/log/kes/audit.log
+    $ kes log trace --dashboard --window=5m --ring-size=50000
+    $ kes log trace --share --share-addr=:7474
+    $ kes log trace --share --share-tls-cert=share.pem --share-tls-key=share-key.pem
+    $ kes log trace --metrics-addr=:9100
 `
 
 func logTrace(args []string) {
@@ -80,17 +149,85 @@ func logTrace(args []string) {
 		typeFlag           string
 		jsonOutput         bool
 		insecureSkipVerify bool
+		sinkName           string
+		sinkURL            string
+		levelFlag          string
+		formatFlag         string
+		dashboard          bool
+		ringSize           int
+		windowFlag         string
+		shareEnabled       bool
+		shareAddr          string
+		shareToken         string
+		shareTLSCert       string
+		shareTLSKey        string
+		shareRelayAddr     string
+		shareIdleTimeout   string
+		metricsAddr        string
+		metricsTopK        int
+		pushgatewayURL     string
 	)
 	cli.StringVar(&typeFlag, "type", "audit", "Log event type [ audit | error ]")
 	cli.BoolVar(&jsonOutput, "json", false, "Print log events as JSON")
 	cli.BoolVar(&insecureSkipVerify, "k", false, "Skip X.509 certificate validation during TLS handshake")
 	cli.BoolVar(&insecureSkipVerify, "insecure", false, "Skip X.509 certificate validation during TLS handshake")
+	cli.StringVar(&sinkName, "sink", "", "Forward log events to a structured sink [ file | syslog | gelf | elasticsearch ]")
+	cli.StringVar(&sinkURL, "sink-url", "", "Sink-specific destination")
+	cli.StringVar(&levelFlag, "level", "info", "Minimum severity forwarded to --sink [ debug | info | warn | error ]")
+	cli.StringVar(&formatFlag, "format", "json", "Wire format used by --sink [ json | logfmt | cef ]")
+	cli.BoolVar(&dashboard, "dashboard", false, "Show a multi-pane dashboard instead of the plain table UI")
+	cli.IntVar(&ringSize, "ring-size", 10000, "Scrollback ring buffer size for --dashboard")
+	cli.StringVar(&windowFlag, "window", "60s", "Width of the rolling stats window for --dashboard")
+	cli.BoolVar(&shareEnabled, "share", false, "Serve the trace over an embedded, read-only HTTP server")
+	cli.StringVar(&shareAddr, "share-addr", ":7474", "Address the share server listens on")
+	cli.StringVar(&shareToken, "share-token", "", "Per-session access token for --share")
+	cli.StringVar(&shareTLSCert, "share-tls-cert", "", "TLS certificate for the share server")
+	cli.StringVar(&shareTLSKey, "share-tls-key", "", "TLS private key for --share-tls-cert")
+	cli.StringVar(&shareRelayAddr, "share-relay-addr", "", "Dial and serve --share over a yamux relay instead of listening locally")
+	cli.StringVar(&shareIdleTimeout, "share-idle-timeout", "30m", "Disconnect a --share viewer after this much time with no activity")
+	cli.StringVar(&metricsAddr, "metrics-addr", "", "Expose Prometheus/OpenMetrics series at http://<addr>/metrics")
+	cli.IntVar(&metricsTopK, "metrics-top-k", 20, "Distinct identity/path label values tracked before collapsing into \"other\"")
+	cli.StringVar(&pushgatewayURL, "pushgateway-url", "", "Push metrics to a Prometheus Pushgateway instead of serving --metrics-addr")
 	cli.Parse(args[1:])
 
 	if cli.NArg() > 0 {
 		stdlog.Fatal("Error: too many arguments")
 	}
 
+	level, err := internallog.ParseLevel(levelFlag)
+	if err != nil {
+		stdlog.Fatalf("Error: %v", err)
+	}
+	format := internallog.Format(strings.ToLower(formatFlag))
+	window, err := time.ParseDuration(windowFlag)
+	if err != nil {
+		stdlog.Fatalf("Error: invalid --window: %v", err)
+	}
+
+	var sink internallog.Sink
+	if sinkName != "" {
+		sink, err = internallog.New(sinkName, sinkURL, format)
+		if err != nil {
+			stdlog.Fatalf("Error: %v", err)
+		}
+		defer sink.Close()
+	}
+
+	var shareSrv *share.Server
+	if shareEnabled {
+		idleTimeout, err := time.ParseDuration(shareIdleTimeout)
+		if err != nil {
+			stdlog.Fatalf("Error: invalid --share-idle-timeout: %v", err)
+		}
+		shareSrv = startShareServer(shareAddr, shareToken, shareTLSCert, shareTLSKey, shareRelayAddr, idleTimeout)
+		defer shareSrv.Close()
+	}
+
+	var collector *metrics.Collector
+	if metricsAddr != "" || pushgatewayURL != "" {
+		collector = startMetricsCollector(metricsAddr, pushgatewayURL, metricsTopK)
+	}
+
 	client := newClient(insecureSkipVerify)
 	switch strings.ToLower(typeFlag) {
 	case "audit":
@@ -101,14 +238,18 @@ func logTrace(args []string) {
 		defer stream.Close()
 
 		if !isTerm(os.Stdout) || jsonOutput {
-			closeOn(stream, os.Interrupt, os.Kill)
-			for stream.Next() {
-				fmt.Println(string(stream.Bytes()))
-			}
-			stdlog.Fatalf("Error: audit log closed with: %v", stream.Err())
+			traceAuditStreamHeadless(stream, client.AuditLog, sink, level, shareSrv, collector)
 			return
 		}
-		traceAuditLogWithUI(stream)
+		if collector != nil {
+			collector.SetStreamConnected("audit", true)
+			defer collector.SetStreamConnected("audit", false)
+		}
+		if dashboard {
+			traceAuditLogWithDashboard(stream, sink, level, ringSize, window, collector)
+		} else {
+			traceAuditLogWithUI(stream, sink, level, shareSrv, collector)
+		}
 	case "error":
 		stream, err := client.ErrorLog()
 		if err != nil {
@@ -117,24 +258,101 @@ func logTrace(args []string) {
 		defer stream.Close()
 
 		if !isTerm(os.Stdout) || jsonOutput {
-			closeOn(stream, os.Interrupt, os.Kill)
-			for stream.Next() {
-				fmt.Println(string(stream.Bytes()))
-			}
-			stdlog.Fatalf("Error: error log closed with: %v", stream.Err())
+			traceErrorStreamHeadless(stream, client.ErrorLog, sink, level, shareSrv, collector)
+			return
+		}
+		if collector != nil {
+			collector.SetStreamConnected("error", true)
+			defer collector.SetStreamConnected("error", false)
+		}
+		if dashboard {
+			traceErrorLogWithDashboard(stream, sink, level, ringSize, window, collector)
+		} else {
+			traceErrorLogWithUI(stream, sink, level, shareSrv, collector)
 		}
-		traceErrorLogWithUI(stream)
 	default:
 		stdlog.Fatalf("Error: invalid log type --type: %q", typeFlag)
 	}
 }
 
+// forwardAuditEvent extracts the structured fields of an audit
+// log event and writes them to sink, if one is configured and
+// the event meets the minimum level.
+func forwardAuditEvent(sink internallog.Sink, level internallog.Level, event kes.AuditEvent, collector *metrics.Collector) {
+	if collector != nil {
+		collector.ObserveAudit(event.Request.Identity, event.Request.Path, event.Response.StatusCode, event.Response.Time)
+	}
+	if sink == nil {
+		return
+	}
+
+	eventLevel := internallog.LevelInfo
+	switch {
+	case event.Response.StatusCode >= http.StatusInternalServerError:
+		eventLevel = internallog.LevelError
+	case event.Response.StatusCode >= http.StatusBadRequest:
+		eventLevel = internallog.LevelWarn
+	}
+	if eventLevel < level {
+		return
+	}
+
+	_ = sink.Write(internallog.Event{
+		Kind:     "audit",
+		Level:    eventLevel,
+		Time:     event.Time,
+		Identity: event.Request.Identity,
+		Path:     event.Request.Path,
+		Status:   event.Response.StatusCode,
+		Fields: map[string]interface{}{
+			"latency": event.Response.Time.String(),
+		},
+	})
+}
+
+// forwardErrorEvent extracts the structured fields of an error
+// log event - its timestamp, severity and message - and writes
+// them to sink, if one is configured and the event meets the
+// minimum level.
+func forwardErrorEvent(sink internallog.Sink, level internallog.Level, event kes.ErrorEvent, collector *metrics.Collector) {
+	if collector != nil {
+		collector.ObserveError()
+	}
+	if sink == nil {
+		return
+	}
+
+	// An error event message has the form "YYYY/MM/DD hh:mm:ss <message>";
+	// see the identical split in traceErrorLogWithUI.
+	segments := strings.SplitN(event.Message, " ", 3)
+	message := event.Message
+	var eventTime time.Time
+	if len(segments) == 3 {
+		message = segments[2]
+		eventTime, _ = time.ParseInLocation("2006/01/02 15:04:05", segments[0]+" "+segments[1], time.Local)
+	}
+
+	eventLevel := internallog.LevelInfo
+	if strings.Contains(strings.ToLower(message), "error") {
+		eventLevel = internallog.LevelError
+	}
+	if eventLevel < level {
+		return
+	}
+	_ = sink.Write(internallog.Event{
+		Kind:    "error",
+		Level:   eventLevel,
+		Time:    eventTime,
+		Message: message,
+	})
+}
+
 // traceAuditLogWithUI iterates over the audit log
 // event stream and prints a table-like UI to STDOUT.
 //
 // Each event is displayed as a new row and the UI is
 // automatically adjusted to the terminal window size.
-func traceAuditLogWithUI(stream *kes.AuditStream) {
+func traceAuditLogWithUI(stream *kes.AuditStream, sink internallog.Sink, level internallog.Level, shareSrv *share.Server, collector *metrics.Collector) {
 	table := xterm.NewTable("Time", "Identity", "Status", "API Operations", "Response")
 	table.Header()[0].Width = 0.12
 	table.Header()[1].Width = 0.15
@@ -206,6 +424,11 @@ func traceAuditLogWithUI(stream *kes.AuditStream) {
 
 		table.AddRow(reqTime, identity, status, path, respTime)
 		table.Draw()
+		forwardAuditEvent(sink, level, event, collector)
+		if shareSrv != nil {
+			shareSrv.Frame([]byte(fmt.Sprintf("%02d:%02d:%02d  %-20s  %-3d  %s\r\n",
+				hh, mm, ss, event.Request.Identity, event.Response.StatusCode, event.Request.Path)))
+		}
 	}
 	if err := stream.Err(); err != nil {
 		stdlog.Fatalf("Error: audit log stream closed with: %v", err)
@@ -217,7 +440,7 @@ func traceAuditLogWithUI(stream *kes.AuditStream) {
 //
 // Each event is displayed as a new row and the UI is
 // automatically adjusted to the terminal window size.
-func traceErrorLogWithUI(stream *kes.ErrorStream) {
+func traceErrorLogWithUI(stream *kes.ErrorStream, sink internallog.Sink, level internallog.Level, shareSrv *share.Server, collector *metrics.Collector) {
 	table := xterm.NewTable("Time", "Error")
 	table.Header()[0].Width = 0.12
 	table.Header()[1].Width = 0.87
@@ -259,20 +482,28 @@ func traceErrorLogWithUI(stream *kes.ErrorStream) {
 		// We replace any '\n' with a whitespace to avoid multi-line table rows.
 		segments := strings.SplitN(stream.Event().Message, " ", 3)
 		var (
-			message *xterm.Cell
-			reqTime *xterm.Cell
+			messageText string
+			reqTimeText string
+			message     *xterm.Cell
+			reqTime     *xterm.Cell
 		)
 		if len(segments) == 3 {
-			message = xterm.NewCell(strings.ReplaceAll(segments[2], "\n", " "))
-			reqTime = xterm.NewCell(segments[1])
+			messageText = strings.ReplaceAll(segments[2], "\n", " ")
+			reqTimeText = segments[1]
 		} else {
 			hh, mm, ss := time.Now().Clock()
 
-			message = xterm.NewCell(strings.ReplaceAll(stream.Event().Message, "\n", " "))
-			reqTime = xterm.NewCell(fmt.Sprintf("%02d:%02d:%02d", hh, mm, ss))
+			messageText = strings.ReplaceAll(stream.Event().Message, "\n", " ")
+			reqTimeText = fmt.Sprintf("%02d:%02d:%02d", hh, mm, ss)
 		}
+		message = xterm.NewCell(messageText)
+		reqTime = xterm.NewCell(reqTimeText)
 		table.AddRow(reqTime, message)
 		table.Draw()
+		forwardErrorEvent(sink, level, stream.Event(), collector)
+		if shareSrv != nil {
+			shareSrv.Frame([]byte(fmt.Sprintf("%s  %s\r\n", reqTimeText, messageText)))
+		}
 	}
 	if err := stream.Err(); err != nil {
 		stdlog.Fatalf("Error: error log stream closed with: %v", err)
@@ -0,0 +1,164 @@
+// Copyright 2020 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	stdlog "log"
+	"os"
+	"time"
+
+	"github.com/minio/kes"
+	internallog "github.com/minio/kes/internal/log"
+	"github.com/minio/kes/internal/metrics"
+	"github.com/minio/kes/internal/xterm"
+
+	ui "github.com/gizak/termui/v3"
+)
+
+// traceAuditLogWithDashboard drives an xterm.Dashboard off the
+// audit log event stream: a filterable, pausable scrollback
+// table plus request-count, error-rate and rolling latency stats.
+func traceAuditLogWithDashboard(stream *kes.AuditStream, sink internallog.Sink, level internallog.Level, ringSize int, window time.Duration, collector *metrics.Collector) {
+	dash := xterm.NewDashboard(xterm.DashboardConfig{
+		Headers:  []string{"Time", "Identity", "Status", "API Operations", "Response"},
+		RingSize: ringSize,
+		Window:   window,
+	})
+
+	if err := ui.Init(); err != nil {
+		stdlog.Fatalf("Error: %v", err)
+	}
+	defer ui.Close()
+
+	w, h := ui.TerminalDimensions()
+	dash.Resize(w, h)
+	ui.Render(dash.Grid())
+
+	ticker := time.NewTicker(dash.RotateInterval())
+	defer ticker.Stop()
+
+	go func() {
+		events := ui.PollEvents()
+		for {
+			select {
+			case <-ticker.C:
+				dash.Rotate()
+			case event := <-events:
+				if runDashboardKeybindings(dash, event, stream) {
+					return
+				}
+			}
+		}
+	}()
+
+	for stream.Next() {
+		event := stream.Event()
+		hh, mm, ss := event.Time.Clock()
+		dash.AddRow(xterm.Row{
+			Columns: []string{
+				fmt.Sprintf("%02d:%02d:%02d", hh, mm, ss),
+				event.Request.Identity,
+				fmt.Sprintf("%d", event.Response.StatusCode),
+				event.Request.Path,
+				event.Response.Time.Truncate(10 * time.Microsecond).String(),
+			},
+			Raw:     event,
+			Status:  event.Response.StatusCode,
+			Latency: event.Response.Time,
+		})
+		forwardAuditEvent(sink, level, event, collector)
+	}
+	if err := stream.Err(); err != nil {
+		stdlog.Fatalf("Error: audit log stream closed with: %v", err)
+	}
+}
+
+// traceErrorLogWithDashboard is the error-log counterpart of
+// traceAuditLogWithDashboard.
+func traceErrorLogWithDashboard(stream *kes.ErrorStream, sink internallog.Sink, level internallog.Level, ringSize int, window time.Duration, collector *metrics.Collector) {
+	dash := xterm.NewDashboard(xterm.DashboardConfig{
+		Headers:  []string{"Time", "Error"},
+		RingSize: ringSize,
+		Window:   window,
+	})
+
+	if err := ui.Init(); err != nil {
+		stdlog.Fatalf("Error: %v", err)
+	}
+	defer ui.Close()
+
+	w, h := ui.TerminalDimensions()
+	dash.Resize(w, h)
+	ui.Render(dash.Grid())
+
+	ticker := time.NewTicker(dash.RotateInterval())
+	defer ticker.Stop()
+
+	go func() {
+		events := ui.PollEvents()
+		for {
+			select {
+			case <-ticker.C:
+				dash.Rotate()
+			case event := <-events:
+				if runDashboardKeybindings(dash, event, stream) {
+					return
+				}
+			}
+		}
+	}()
+
+	for stream.Next() {
+		hh, mm, ss := time.Now().Clock()
+		event := stream.Event()
+		dash.AddRow(xterm.Row{
+			Columns: []string{fmt.Sprintf("%02d:%02d:%02d", hh, mm, ss), event.Message},
+			Raw:     event,
+		})
+		forwardErrorEvent(sink, level, event, collector)
+	}
+	if err := stream.Err(); err != nil {
+		stdlog.Fatalf("Error: error log stream closed with: %v", err)
+	}
+}
+
+// runDashboardKeybindings applies a single termui key event to
+// dash - filter editing, pause/resume, row selection and quit -
+// and reports whether the event loop should stop.
+func runDashboardKeybindings(dash *xterm.Dashboard, event ui.Event, closer interface{ Close() error }) bool {
+	if event.Type == ui.ResizeEvent {
+		payload := event.Payload.(ui.Resize)
+		dash.Resize(payload.Width, payload.Height)
+		ui.Render(dash.Grid())
+		return false
+	}
+
+	if dash.EditingFilter() {
+		dash.FilterKey(event.ID)
+		return false
+	}
+
+	switch event.ID {
+	case "<C-c>", "q":
+		if err := closer.Close(); err != nil {
+			fmt.Fprintln(os.Stderr, fmt.Sprintf("Error: log stream closed with: %v", err))
+		}
+		return true
+	case "/":
+		dash.BeginFilterEdit()
+	case "p":
+		if dash.Paused() {
+			dash.Resume()
+		} else {
+			dash.Pause()
+		}
+	case "<Down>", "j":
+		dash.Select(1)
+	case "<Up>", "k":
+		dash.Select(-1)
+	}
+	return false
+}
@@ -0,0 +1,216 @@
+// Copyright 2020 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	stdlog "log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/minio/kes"
+	internallog "github.com/minio/kes/internal/log"
+	"github.com/minio/kes/internal/metrics"
+	"github.com/minio/kes/internal/share"
+)
+
+const pushgatewayInterval = 15 * time.Second
+
+const (
+	reconnectMinBackoff = time.Second
+	reconnectMaxBackoff = 30 * time.Second
+)
+
+// startMetricsCollector creates a metrics.Collector and, depending
+// on which of addr / pushgatewayURL is set, either serves it at
+// http://addr/metrics for pull-based scraping or pushes it to a
+// Pushgateway on a fixed interval. Exactly one of the two modes
+// runs; if both are given, pushing takes precedence.
+func startMetricsCollector(addr, pushgatewayURL string, topK int) *metrics.Collector {
+	collector := metrics.New(metrics.Config{TopK: topK})
+
+	switch {
+	case pushgatewayURL != "":
+		go func() {
+			err := collector.PushLoop(context.Background(), pushgatewayURL, "kes_log_trace", pushgatewayInterval)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, fmt.Sprintf("Error: pushgateway loop stopped: %v", err))
+			}
+		}()
+		stdlog.Printf("Pushing metrics to %s every %s", pushgatewayURL, pushgatewayInterval)
+	case addr != "":
+		go func() {
+			if err := collector.Serve(addr); err != nil {
+				fmt.Fprintln(os.Stderr, fmt.Sprintf("Error: metrics server stopped: %v", err))
+			}
+		}()
+		stdlog.Printf("Exposing metrics at http://%s/metrics", addr)
+	}
+	return collector
+}
+
+// reconnectCloser lets closeOn keep interrupting the active
+// stream across reconnects: Close forwards to whichever closer
+// was most recently set, instead of being bound to the one
+// stream that existed when closeOn was called. It also records
+// that the close was requested by the user (a signal, via
+// closeOn) rather than by the stream dropping on its own, so
+// the reconnect loop can tell the two apart.
+type reconnectCloser struct {
+	mu        sync.Mutex
+	c         io.Closer
+	requested bool
+}
+
+func (r *reconnectCloser) set(c io.Closer) {
+	r.mu.Lock()
+	r.c = c
+	r.mu.Unlock()
+}
+
+func (r *reconnectCloser) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requested = true
+	if r.c == nil {
+		return nil
+	}
+	return r.c.Close()
+}
+
+// closedByUser reports whether Close has already been called,
+// i.e. the current disconnect is Ctrl-C/SIGTERM rather than the
+// stream dropping on its own.
+func (r *reconnectCloser) closedByUser() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requested
+}
+
+// traceAuditStreamHeadless is the non-terminal counterpart of
+// traceAuditLogWithUI: it prints every audit event as newline-
+// delimited JSON and forwards it to sink/shareSrv. If collector
+// is set it reconnects with backoff on a dropped stream instead
+// of exiting, so a --metrics-addr sidecar survives a transient
+// disconnect from the KES server, and records each reconnect as
+// kes_stream_reconnects_total. Without a collector there is
+// nowhere to surface that as a health signal, so - matching the
+// plain `trace` behavior - a single disconnect is still fatal.
+func traceAuditStreamHeadless(stream *kes.AuditStream, connect func() (*kes.AuditStream, error), sink internallog.Sink, level internallog.Level, shareSrv *share.Server, collector *metrics.Collector) {
+	closer := &reconnectCloser{}
+	closer.set(stream)
+	closeOn(closer, os.Interrupt, os.Kill)
+
+	backoff := reconnectMinBackoff
+	for {
+		if collector != nil {
+			collector.SetStreamConnected("audit", true)
+		}
+		for stream.Next() {
+			fmt.Println(string(stream.Bytes()))
+			forwardAuditEvent(sink, level, stream.Event(), collector)
+			if shareSrv != nil {
+				shareSrv.NDJSON(stream.Bytes())
+			}
+		}
+		err := stream.Err()
+		stream.Close()
+		if collector != nil {
+			collector.SetStreamConnected("audit", false)
+		}
+		if closer.closedByUser() {
+			if err != nil {
+				stdlog.Fatalf("Error: audit log closed with: %v", err)
+			}
+			return
+		}
+		if err == nil || collector == nil {
+			stdlog.Fatalf("Error: audit log closed with: %v", err)
+		}
+
+		collector.IncReconnect("audit")
+		stdlog.Printf("Warning: audit log stream disconnected: %v; reconnecting in %s", err, backoff)
+		time.Sleep(backoff)
+		if backoff < reconnectMaxBackoff {
+			backoff *= 2
+		}
+
+		stream, err = connect()
+		for err != nil {
+			if closer.closedByUser() {
+				return
+			}
+			stdlog.Printf("Warning: failed to reconnect to audit log: %v; retrying in %s", err, backoff)
+			time.Sleep(backoff)
+			if backoff < reconnectMaxBackoff {
+				backoff *= 2
+			}
+			stream, err = connect()
+		}
+		closer.set(stream)
+		backoff = reconnectMinBackoff
+	}
+}
+
+// traceErrorStreamHeadless is the error-log counterpart of
+// traceAuditStreamHeadless.
+func traceErrorStreamHeadless(stream *kes.ErrorStream, connect func() (*kes.ErrorStream, error), sink internallog.Sink, level internallog.Level, shareSrv *share.Server, collector *metrics.Collector) {
+	closer := &reconnectCloser{}
+	closer.set(stream)
+	closeOn(closer, os.Interrupt, os.Kill)
+
+	backoff := reconnectMinBackoff
+	for {
+		if collector != nil {
+			collector.SetStreamConnected("error", true)
+		}
+		for stream.Next() {
+			fmt.Println(string(stream.Bytes()))
+			forwardErrorEvent(sink, level, stream.Event(), collector)
+			if shareSrv != nil {
+				shareSrv.NDJSON(stream.Bytes())
+			}
+		}
+		err := stream.Err()
+		stream.Close()
+		if collector != nil {
+			collector.SetStreamConnected("error", false)
+		}
+		if closer.closedByUser() {
+			if err != nil {
+				stdlog.Fatalf("Error: error log closed with: %v", err)
+			}
+			return
+		}
+		if err == nil || collector == nil {
+			stdlog.Fatalf("Error: error log closed with: %v", err)
+		}
+
+		collector.IncReconnect("error")
+		stdlog.Printf("Warning: error log stream disconnected: %v; reconnecting in %s", err, backoff)
+		time.Sleep(backoff)
+		if backoff < reconnectMaxBackoff {
+			backoff *= 2
+		}
+
+		stream, err = connect()
+		for err != nil {
+			if closer.closedByUser() {
+				return
+			}
+			stdlog.Printf("Warning: failed to reconnect to error log: %v; retrying in %s", err, backoff)
+			time.Sleep(backoff)
+			if backoff < reconnectMaxBackoff {
+				backoff *= 2
+			}
+			stream, err = connect()
+		}
+		closer.set(stream)
+		backoff = reconnectMinBackoff
+	}
+}
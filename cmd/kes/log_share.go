@@ -0,0 +1,65 @@
+// Copyright 2020 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	stdlog "log"
+	"os"
+	"time"
+
+	"github.com/minio/kes/internal/share"
+)
+
+// startShareServer starts the read-only trace-sharing HTTP
+// server in the background and prints the URL teammates can use
+// to follow along. It never forwards viewer input back into the
+// traced CLI - see internal/share for the read-only guarantee.
+//
+// If tlsCert and tlsKey are given the server serves HTTPS/WSS
+// instead of plain HTTP/WS. If relayAddr is given the session is
+// served over a yamux connection dialed to that address instead
+// of a local listener, so viewers behind NAT can still reach it
+// through a public relay. idleTimeout bounds how long a viewer
+// connection may sit with no activity before it is dropped.
+func startShareServer(addr, token, tlsCert, tlsKey, relayAddr string, idleTimeout time.Duration) *share.Server {
+	var tlsConfig *tls.Config
+	if tlsCert != "" || tlsKey != "" {
+		cert, err := tls.LoadX509KeyPair(tlsCert, tlsKey)
+		if err != nil {
+			stdlog.Fatalf("Error: failed to load --share-tls-cert/--share-tls-key: %v", err)
+		}
+		tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+
+	srv := share.New(share.Config{Addr: addr, Token: token, IdleTimeout: idleTimeout, TLSConfig: tlsConfig})
+
+	scheme, host := "http", addr
+	if tlsConfig != nil {
+		scheme = "https"
+	}
+	if host == "" || host[0] == ':' {
+		host = "localhost" + host
+	}
+
+	if relayAddr != "" {
+		go func() {
+			if err := srv.ServeRelay(relayAddr, tlsConfig); err != nil {
+				fmt.Fprintln(os.Stderr, fmt.Sprintf("Error: share relay stopped: %v", err))
+			}
+		}()
+		stdlog.Printf("Sharing this trace via relay %s with token %s (read-only)", relayAddr, srv.Token())
+		return srv
+	}
+
+	go func() {
+		if err := srv.Serve(); err != nil {
+			fmt.Fprintln(os.Stderr, fmt.Sprintf("Error: share server stopped: %v", err))
+		}
+	}()
+	stdlog.Printf("Sharing this trace at %s://%s/?token=%s (read-only)", scheme, host, srv.Token())
+	return srv
+}
@@ -0,0 +1,236 @@
+// Copyright 2020 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	stdlog "log"
+	"os"
+	"time"
+
+	"github.com/minio/kes/internal/filter"
+)
+
+const tailLogCmdUsage = `Usage:
+    kes log tail [options]
+
+Options:
+    --type {audit|error}   Specify the log event type.
+                           Valid options are:
+                             --type=audit (default)
+                             --type=error
+
+    --since <duration|time>
+                           Replay events no older than the given duration
+                           (e.g. "1h", "15m") or RFC3339 timestamp before
+                           switching into live-follow mode. (default: 0, off)
+    --tail <n>             Show only the last n replayed events. (default: 1000)
+    --follow               Keep streaming new events after the replay.
+                           (default: true; pass --follow=false to replay
+                           the backlog and exit)
+    --filter <expr>        Server-side filter expression, e.g.:
+                             --filter='identity=~"admin.*" and status>=400'
+                           The filter is evaluated by the server, so only
+                           matching events are ever sent to the client.
+    --json                 Print log events as JSON.
+    -k, --insecure         Skip X.509 certificate validation during TLS handshake.
+    -h, --help             Show list of command-line options.
+
+Pulls historical log events from the KES server and then, unless
+--follow=false, switches to a live follow stream - like 'journalctl -f'
+or 'kubectl logs --since'. The replay window and filter are applied by
+the server, so the client never receives events it did not ask for;
+--tail has no server-side equivalent, so the full matching backlog is
+replayed and only the last n events are printed.
+
+Examples:
+    $ kes log tail --since=1h --tail=1000
+    $ kes log tail --follow --filter='identity=~"admin.*" and status>=400'
+`
+
+func logTail(args []string) {
+	cli := flag.NewFlagSet(args[0], flag.ExitOnError)
+	cli.Usage = func() { fmt.Fprintf(os.Stderr, tailLogCmdUsage) }
+
+	var (
+		typeFlag           string
+		sinceFlag          string
+		tailCount          int
+		follow             bool
+		filterExpr         string
+		jsonOutput         bool
+		insecureSkipVerify bool
+	)
+	cli.StringVar(&typeFlag, "type", "audit", "Log event type [ audit | error ]")
+	cli.StringVar(&sinceFlag, "since", "", "Replay events no older than this duration or RFC3339 timestamp")
+	cli.IntVar(&tailCount, "tail", 1000, "Print only the last n replayed events")
+	cli.BoolVar(&follow, "follow", true, "Keep streaming new events after the replay")
+	cli.StringVar(&filterExpr, "filter", "", "Server-side filter expression")
+	cli.BoolVar(&jsonOutput, "json", false, "Print log events as JSON")
+	cli.BoolVar(&insecureSkipVerify, "k", false, "Skip X.509 certificate validation during TLS handshake")
+	cli.BoolVar(&insecureSkipVerify, "insecure", false, "Skip X.509 certificate validation during TLS handshake")
+	cli.Parse(args[1:])
+
+	if cli.NArg() > 0 {
+		stdlog.Fatal("Error: too many arguments")
+	}
+
+	since, err := parseSince(sinceFlag)
+	if err != nil {
+		stdlog.Fatalf("Error: invalid --since: %v", err)
+	}
+
+	f, err := filter.Parse(filterExpr)
+	if err != nil {
+		stdlog.Fatalf("Error: invalid --filter: %v", err)
+	}
+
+	// Bound the replay to events strictly before "now" so the range
+	// stream is finite and fully drains on its own - as opposed to
+	// passing a zero until, which would leave the server free to
+	// keep the stream open past the historical backlog and merge
+	// live events into it before --tail has had a chance to trim.
+	until := time.Now()
+
+	client := newClient(insecureSkipVerify)
+	switch typeFlag {
+	case "audit":
+		stream, err := client.AuditLogRange(since, until, f.Encode())
+		if err != nil {
+			stdlog.Fatalf("Error: failed to replay audit log: %v", err)
+		}
+
+		replay := newLineRing(tailCount)
+		for stream.Next() {
+			replay.add(stream.Bytes())
+		}
+		err = stream.Err()
+		stream.Close()
+		if err != nil {
+			stdlog.Fatalf("Error: audit log replay closed with: %v", err)
+		}
+		replay.each(func(line []byte) { fmt.Println(string(line)) })
+		if !follow {
+			return
+		}
+
+		// Continue from exactly where the replay left off, with a
+		// zero until so the server keeps the connection open past
+		// the backlog - there's no gap to lose events in and no
+		// unfiltered client.AuditLog() that would bypass --filter.
+		live, err := client.AuditLogRange(until, time.Time{}, f.Encode())
+		if err != nil {
+			stdlog.Fatalf("Error: failed to follow audit log: %v", err)
+		}
+		defer live.Close()
+		closeOn(live, os.Interrupt, os.Kill)
+
+		if !jsonOutput && isTerm(os.Stdout) {
+			traceAuditLogWithUI(live, nil, 0, nil, nil)
+			return
+		}
+		for live.Next() {
+			fmt.Println(string(live.Bytes()))
+		}
+	case "error":
+		stream, err := client.ErrorLogRange(since, until, f.Encode())
+		if err != nil {
+			stdlog.Fatalf("Error: failed to replay error log: %v", err)
+		}
+
+		replay := newLineRing(tailCount)
+		for stream.Next() {
+			replay.add(stream.Bytes())
+		}
+		err = stream.Err()
+		stream.Close()
+		if err != nil {
+			stdlog.Fatalf("Error: error log replay closed with: %v", err)
+		}
+		replay.each(func(line []byte) { fmt.Println(string(line)) })
+		if !follow {
+			return
+		}
+
+		// Continue from exactly where the replay left off, with a
+		// zero until so the server keeps the connection open past
+		// the backlog - there's no gap to lose events in and no
+		// unfiltered client.ErrorLog() that would bypass --filter.
+		live, err := client.ErrorLogRange(until, time.Time{}, f.Encode())
+		if err != nil {
+			stdlog.Fatalf("Error: failed to follow error log: %v", err)
+		}
+		defer live.Close()
+		closeOn(live, os.Interrupt, os.Kill)
+
+		if !jsonOutput && isTerm(os.Stdout) {
+			traceErrorLogWithUI(live, nil, 0, nil, nil)
+			return
+		}
+		for live.Next() {
+			fmt.Println(string(live.Bytes()))
+		}
+	default:
+		stdlog.Fatalf("Error: invalid log type --type: %q", typeFlag)
+	}
+}
+
+// lineRing retains only the last n lines handed to add, discarding
+// older ones as new ones arrive - the client-side equivalent of a
+// server-side "last N" bound, since AuditLogRange/ErrorLogRange
+// take no tail parameter of their own. n <= 0 means unbounded.
+type lineRing struct {
+	lines [][]byte
+	cap   int
+	head  int
+	count int
+}
+
+func newLineRing(n int) *lineRing {
+	if n <= 0 {
+		return &lineRing{}
+	}
+	return &lineRing{lines: make([][]byte, n), cap: n}
+}
+
+func (r *lineRing) add(line []byte) {
+	if r.cap == 0 {
+		r.lines = append(r.lines, append([]byte(nil), line...))
+		return
+	}
+	r.lines[r.head] = append([]byte(nil), line...)
+	r.head = (r.head + 1) % r.cap
+	if r.count < r.cap {
+		r.count++
+	}
+}
+
+// each calls fn with every retained line, oldest first.
+func (r *lineRing) each(fn func(line []byte)) {
+	if r.cap == 0 {
+		for _, line := range r.lines {
+			fn(line)
+		}
+		return
+	}
+	start := (r.head - r.count + r.cap) % r.cap
+	for i := 0; i < r.count; i++ {
+		fn(r.lines[(start+i)%r.cap])
+	}
+}
+
+// parseSince parses the --since flag as either a duration
+// relative to now (e.g. "1h") or an absolute RFC3339 timestamp.
+// An empty string means "no lower bound".
+func parseSince(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
@@ -0,0 +1,50 @@
+// Copyright 2020 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func collect(r *lineRing) []string {
+	var got []string
+	r.each(func(line []byte) { got = append(got, string(line)) })
+	return got
+}
+
+func TestLineRingBounded(t *testing.T) {
+	r := newLineRing(3)
+	for _, s := range []string{"a", "b", "c", "d", "e"} {
+		r.add([]byte(s))
+	}
+
+	want := []string{"c", "d", "e"}
+	if got := collect(r); !reflect.DeepEqual(got, want) {
+		t.Fatalf("collect() = %v, want %v", got, want)
+	}
+}
+
+func TestLineRingUnbounded(t *testing.T) {
+	r := newLineRing(0)
+	for _, s := range []string{"a", "b", "c"} {
+		r.add([]byte(s))
+	}
+
+	want := []string{"a", "b", "c"}
+	if got := collect(r); !reflect.DeepEqual(got, want) {
+		t.Fatalf("collect() = %v, want %v", got, want)
+	}
+}
+
+func TestLineRingFewerThanCapacity(t *testing.T) {
+	r := newLineRing(10)
+	r.add([]byte("only"))
+
+	want := []string{"only"}
+	if got := collect(r); !reflect.DeepEqual(got, want) {
+		t.Fatalf("collect() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2020 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+// Package filter parses the small boolean expression language
+// accepted by `kes log tail --filter` and turns it into a
+// compact query string the KES server can apply server-side -
+// so that `tail` ships only the events a client actually asked
+// for instead of the whole stream.
+package filter
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Filter is a parsed `--filter` expression. A zero Filter
+// matches every event.
+type Filter struct {
+	IdentityGlob string // identity=~"glob"
+	PathRegex    string // path=~"regex"
+	MinStatus    int    // status>=N
+	MaxStatus    int    // status<=N
+}
+
+// Parse parses an expression of the form:
+//
+//	identity=~"admin.*" and status>=400
+//
+// Clauses are joined with "and" (case-insensitive); the
+// supported clauses are identity=~"glob", path=~"regex",
+// status>=N and status<=N.
+func Parse(expr string) (Filter, error) {
+	f := Filter{MaxStatus: 999}
+	if strings.TrimSpace(expr) == "" {
+		return f, nil
+	}
+
+	for _, clause := range splitAnd(expr) {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+		if err := f.applyClause(clause); err != nil {
+			return Filter{}, err
+		}
+	}
+	return f, nil
+}
+
+func splitAnd(expr string) []string {
+	// A simple case-insensitive split on the " and " keyword.
+	// The filter language has no nesting or quoting around
+	// "and" itself, so this is sufficient.
+	lower := strings.ToLower(expr)
+	var clauses []string
+	for {
+		i := strings.Index(lower, " and ")
+		if i < 0 {
+			clauses = append(clauses, expr)
+			break
+		}
+		clauses = append(clauses, expr[:i])
+		expr, lower = expr[i+5:], lower[i+5:]
+	}
+	return clauses
+}
+
+func (f *Filter) applyClause(clause string) error {
+	switch {
+	case strings.HasPrefix(clause, "identity=~"):
+		f.IdentityGlob = unquote(strings.TrimPrefix(clause, "identity=~"))
+	case strings.HasPrefix(clause, "path=~"):
+		f.PathRegex = unquote(strings.TrimPrefix(clause, "path=~"))
+	case strings.HasPrefix(clause, "status>="):
+		n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(clause, "status>=")))
+		if err != nil {
+			return fmt.Errorf("filter: invalid status>= value in %q", clause)
+		}
+		f.MinStatus = n
+	case strings.HasPrefix(clause, "status<="):
+		n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(clause, "status<=")))
+		if err != nil {
+			return fmt.Errorf("filter: invalid status<= value in %q", clause)
+		}
+		f.MaxStatus = n
+	default:
+		return fmt.Errorf("filter: invalid clause %q", clause)
+	}
+	return nil
+}
+
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// Encode serializes f into the query string the KES server's
+// range endpoint expects, e.g. "identity=admin.%2A&status_gte=400".
+// Values are escaped with url.QueryEscape so glob/regex clauses
+// containing "&", "=", "+" or whitespace survive a round-trip
+// through a standard query-string parser on the server side.
+func (f Filter) Encode() string {
+	values := url.Values{}
+	if f.IdentityGlob != "" {
+		values.Set("identity", f.IdentityGlob)
+	}
+	if f.PathRegex != "" {
+		values.Set("path", f.PathRegex)
+	}
+	if f.MinStatus != 0 {
+		values.Set("status_gte", fmt.Sprintf("%d", f.MinStatus))
+	}
+	if f.MaxStatus != 0 && f.MaxStatus != 999 {
+		values.Set("status_lte", fmt.Sprintf("%d", f.MaxStatus))
+	}
+	return values.Encode()
+}
@@ -0,0 +1,65 @@
+// Copyright 2020 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package filter
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	for _, test := range []struct {
+		expr string
+		want Filter
+	}{
+		{expr: "", want: Filter{MaxStatus: 999}},
+		{
+			expr: `identity=~"admin.*" and status>=400`,
+			want: Filter{IdentityGlob: "admin.*", MinStatus: 400, MaxStatus: 999},
+		},
+		{
+			expr: `path=~"/v1/key/.*" AND status<=499`,
+			want: Filter{PathRegex: "/v1/key/.*", MaxStatus: 499},
+		},
+		{
+			expr: ` identity=~"bob"  and  status>=400  and  status<=499 `,
+			want: Filter{IdentityGlob: "bob", MinStatus: 400, MaxStatus: 499},
+		},
+	} {
+		got, err := Parse(test.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): unexpected error: %v", test.expr, err)
+		}
+		if got != test.want {
+			t.Fatalf("Parse(%q) = %+v, want %+v", test.expr, got, test.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"status>=not-a-number",
+		"status<=",
+		"bogus=~\"x\"",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Fatalf("Parse(%q): expected an error, got nil", expr)
+		}
+	}
+}
+
+func TestFilterEncodeEscapesSpecialCharacters(t *testing.T) {
+	f := Filter{IdentityGlob: "admin & co", PathRegex: "/foo+bar baz", MinStatus: 400, MaxStatus: 999}
+
+	got := f.Encode()
+	want := "identity=admin+%26+co&path=%2Ffoo%2Bbar+baz&status_gte=400"
+	if got != want {
+		t.Fatalf("Encode() = %q, want %q", got, want)
+	}
+}
+
+func TestFilterEncodeOmitsDefaults(t *testing.T) {
+	f := Filter{MaxStatus: 999}
+	if got := f.Encode(); got != "" {
+		t.Fatalf("Encode() of a zero filter = %q, want empty string", got)
+	}
+}
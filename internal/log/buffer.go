@@ -0,0 +1,68 @@
+// Copyright 2020 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"fmt"
+	"sync"
+)
+
+// bufferedSink decouples the goroutine producing Events from
+// the - potentially slow - Sink that delivers them, by running
+// the sink on a dedicated goroutine and communicating over a
+// bounded channel. Once the channel is full, Write drops the
+// event instead of blocking so that a stalled sink can never
+// stall the log stream it is attached to.
+type bufferedSink struct {
+	sink    Sink
+	events  chan Event
+	done    chan struct{}
+	dropped uint64
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// Buffered wraps sink in a bounded, asynchronous buffer of the
+// given capacity. Capacity must be > 0.
+func Buffered(sink Sink, capacity int) Sink {
+	b := &bufferedSink{
+		sink:   sink,
+		events: make(chan Event, capacity),
+		done:   make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+func (b *bufferedSink) run() {
+	defer close(b.done)
+	for e := range b.events {
+		// Errors are swallowed here by design - a sink failing
+		// to deliver a single event must not bring down the
+		// stream it is attached to. Sinks that need visibility
+		// into delivery failures should log them internally.
+		_ = b.sink.Write(e)
+	}
+}
+
+func (b *bufferedSink) Write(e Event) error {
+	select {
+	case b.events <- e:
+		return nil
+	default:
+		b.dropped++
+		return fmt.Errorf("log: sink backpressure - dropped event (%d total)", b.dropped)
+	}
+}
+
+func (b *bufferedSink) Close() error {
+	b.closeOnce.Do(func() {
+		close(b.events)
+		<-b.done
+		b.closeErr = b.sink.Close()
+	})
+	return b.closeErr
+}
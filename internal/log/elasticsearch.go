@@ -0,0 +1,114 @@
+// Copyright 2020 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+func init() {
+	Register("elasticsearch", newElasticsearchSink)
+}
+
+const (
+	esBulkSize     = 200
+	esFlushTimeout = 2 * time.Second
+)
+
+// elasticsearchSink batches events and ships them to an
+// Elasticsearch cluster via the _bulk API. Events are buffered
+// until either esBulkSize events have accumulated or
+// esFlushTimeout has elapsed since the first buffered event,
+// whichever happens first.
+type elasticsearchSink struct {
+	url    string
+	index  string
+	client *http.Client
+
+	mu      sync.Mutex
+	pending []Event
+	timer   *time.Timer
+}
+
+// newElasticsearchSink ignores format: the _bulk API requires a
+// JSON document per event, so --format has no effect on this sink.
+func newElasticsearchSink(url string, _ Format) (Sink, error) {
+	s := &elasticsearchSink{
+		url:    url,
+		index:  "kes-logs",
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+	return s, nil
+}
+
+func (s *elasticsearchSink) Write(e Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending = append(s.pending, e)
+	if s.timer == nil {
+		s.timer = time.AfterFunc(esFlushTimeout, func() { s.flush() })
+	}
+	if len(s.pending) >= esBulkSize {
+		return s.flushLocked()
+	}
+	return nil
+}
+
+func (s *elasticsearchSink) flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flushLocked()
+}
+
+// flushLocked POSTs the pending events to the _bulk endpoint.
+// Callers must hold s.mu.
+func (s *elasticsearchSink) flushLocked() error {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if len(s.pending) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	for _, e := range s.pending {
+		fmt.Fprintf(&body, `{"index":{"_index":%q}}`+"\n", s.index)
+		doc, err := encodeJSON(e)
+		if err != nil {
+			continue
+		}
+		body.Write(doc)
+		body.WriteByte('\n')
+	}
+	s.pending = s.pending[:0]
+
+	req, err := http.NewRequest(http.MethodPost, s.url+"/_bulk", &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var buf bytes.Buffer
+		json.NewEncoder(&buf).Encode(map[string]int{"status": resp.StatusCode})
+		return fmt.Errorf("log: elasticsearch bulk request failed: %s", buf.String())
+	}
+	return nil
+}
+
+func (s *elasticsearchSink) Close() error { return s.flush() }
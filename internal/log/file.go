@@ -0,0 +1,96 @@
+// Copyright 2020 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+func init() {
+	Register("file", newFileSink)
+}
+
+// fileSink appends encoded events to a file, one per line. It
+// reopens the file whenever it receives SIGHUP so that external
+// log rotation (logrotate, etc.) can move the old file out of
+// the way without losing events.
+type fileSink struct {
+	path   string
+	format Format
+
+	mu     sync.Mutex
+	file   *os.File
+	sigCh  chan os.Signal
+	closed chan struct{}
+}
+
+func newFileSink(url string, format Format) (Sink, error) {
+	f, err := openAppend(url)
+	if err != nil {
+		return nil, err
+	}
+	if format == "" {
+		format = FormatJSON
+	}
+
+	s := &fileSink{
+		path:   url,
+		format: format,
+		file:   f,
+		sigCh:  make(chan os.Signal, 1),
+		closed: make(chan struct{}),
+	}
+	signal.Notify(s.sigCh, syscall.SIGHUP)
+	go s.reopenOnSIGHUP()
+	return s, nil
+}
+
+func openAppend(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+}
+
+func (s *fileSink) reopenOnSIGHUP() {
+	for {
+		select {
+		case <-s.sigCh:
+			f, err := openAppend(s.path)
+			if err != nil {
+				continue
+			}
+			s.mu.Lock()
+			old := s.file
+			s.file = f
+			s.mu.Unlock()
+			old.Close()
+		case <-s.closed:
+			return
+		}
+	}
+}
+
+func (s *fileSink) Write(e Event) error {
+	line, err := s.format.Encode(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(line)
+	return err
+}
+
+func (s *fileSink) Close() error {
+	signal.Stop(s.sigCh)
+	close(s.closed)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
@@ -0,0 +1,155 @@
+// Copyright 2020 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Format encodes an Event into one of the line-based formats
+// that the file and syslog sinks accept. The GELF and
+// Elasticsearch sinks always send JSON and ignore Format.
+type Format string
+
+// Supported wire formats for the --format flag.
+const (
+	FormatJSON   Format = "json"
+	FormatLogfmt Format = "logfmt"
+	FormatCEF    Format = "cef"
+)
+
+// Encode renders e according to f. The returned bytes never
+// contain a trailing newline.
+func (f Format) Encode(e Event) ([]byte, error) {
+	switch f {
+	case FormatJSON, "":
+		return encodeJSON(e)
+	case FormatLogfmt:
+		return encodeLogfmt(e), nil
+	case FormatCEF:
+		return encodeCEF(e), nil
+	default:
+		return nil, fmt.Errorf("log: invalid format %q", f)
+	}
+}
+
+func encodeJSON(e Event) ([]byte, error) {
+	fields := map[string]interface{}{
+		"kind": e.Kind,
+	}
+	for k, v := range e.Fields {
+		fields[k] = v
+	}
+	if !e.Time.IsZero() {
+		fields["time"] = e.Time.Format(time.RFC3339Nano)
+	}
+	if e.Identity != "" {
+		fields["identity"] = e.Identity
+	}
+	if e.Path != "" {
+		fields["path"] = e.Path
+	}
+	if e.Status != 0 {
+		fields["status"] = e.Status
+	}
+	if e.Message != "" {
+		fields["message"] = e.Message
+	}
+	return json.Marshal(fields)
+}
+
+func encodeLogfmt(e Event) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "kind=%s", e.Kind)
+	if !e.Time.IsZero() {
+		fmt.Fprintf(&b, " time=%s", e.Time.Format(time.RFC3339Nano))
+	}
+	if e.Identity != "" {
+		fmt.Fprintf(&b, " identity=%s", logfmtQuote(e.Identity))
+	}
+	if e.Path != "" {
+		fmt.Fprintf(&b, " path=%s", logfmtQuote(e.Path))
+	}
+	if e.Status != 0 {
+		fmt.Fprintf(&b, " status=%d", e.Status)
+	}
+	if e.Message != "" {
+		fmt.Fprintf(&b, " message=%s", logfmtQuote(e.Message))
+	}
+
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, " %s=%s", k, logfmtQuote(fmt.Sprint(e.Fields[k])))
+	}
+	return []byte(b.String())
+}
+
+func logfmtQuote(s string) string {
+	if strings.ContainsAny(s, " \t\"=") {
+		return strconvQuote(s)
+	}
+	return s
+}
+
+func strconvQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		if r == '"' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// encodeCEF renders e as an ArcSight Common Event Format line,
+// the format most SIEMs expect from a syslog-fed log source.
+func encodeCEF(e Event) []byte {
+	severity := 3
+	switch e.Level {
+	case LevelDebug:
+		severity = 1
+	case LevelInfo:
+		severity = 3
+	case LevelWarn:
+		severity = 6
+	case LevelError:
+		severity = 9
+	}
+
+	name := "AuditEvent"
+	if e.Kind == "error" {
+		name = "ErrorEvent"
+	}
+
+	var ext strings.Builder
+	if !e.Time.IsZero() {
+		fmt.Fprintf(&ext, "rt=%d ", e.Time.UnixMilli())
+	}
+	if e.Identity != "" {
+		fmt.Fprintf(&ext, "suser=%s ", e.Identity)
+	}
+	if e.Path != "" {
+		fmt.Fprintf(&ext, "request=%s ", e.Path)
+	}
+	if e.Status != 0 {
+		fmt.Fprintf(&ext, "outcome=%d ", e.Status)
+	}
+	if e.Message != "" {
+		fmt.Fprintf(&ext, "msg=%s ", strings.ReplaceAll(e.Message, "\n", " "))
+	}
+
+	return []byte(fmt.Sprintf("CEF:0|MinIO|KES|0|%s|%s|%d|%s", e.Kind, name, severity, strings.TrimSpace(ext.String())))
+}
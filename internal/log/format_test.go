@@ -0,0 +1,76 @@
+// Copyright 2020 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testEvent() Event {
+	return Event{
+		Kind:     "audit",
+		Level:    LevelWarn,
+		Time:     time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		Identity: "admin",
+		Path:     "/v1/key/create",
+		Status:   403,
+	}
+}
+
+func TestFormatEncodeJSON(t *testing.T) {
+	raw, err := FormatJSON.Encode(testEvent())
+	if err != nil {
+		t.Fatalf("Encode: unexpected error: %v", err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		t.Fatalf("Encode did not produce valid JSON: %v", err)
+	}
+	if fields["identity"] != "admin" || fields["path"] != "/v1/key/create" {
+		t.Fatalf("unexpected fields: %+v", fields)
+	}
+	if fields["time"] != "2024-03-01T12:00:00Z" {
+		t.Fatalf("time field = %v, want RFC3339Nano timestamp", fields["time"])
+	}
+}
+
+func TestFormatEncodeLogfmt(t *testing.T) {
+	raw, err := FormatLogfmt.Encode(testEvent())
+	if err != nil {
+		t.Fatalf("Encode: unexpected error: %v", err)
+	}
+
+	got := string(raw)
+	for _, want := range []string{"kind=audit", "identity=admin", "status=403", "time=2024-03-01T12:00:00Z"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("Encode() = %q, missing %q", got, want)
+		}
+	}
+}
+
+func TestFormatEncodeCEF(t *testing.T) {
+	raw, err := FormatCEF.Encode(testEvent())
+	if err != nil {
+		t.Fatalf("Encode: unexpected error: %v", err)
+	}
+
+	got := string(raw)
+	if !strings.HasPrefix(got, "CEF:0|MinIO|KES|0|audit|AuditEvent|6|") {
+		t.Fatalf("Encode() = %q, unexpected CEF header", got)
+	}
+	if !strings.Contains(got, "rt=1709294400000") {
+		t.Fatalf("Encode() = %q, missing rt= timestamp extension", got)
+	}
+}
+
+func TestFormatEncodeInvalid(t *testing.T) {
+	if _, err := Format("bogus").Encode(testEvent()); err == nil {
+		t.Fatal("Encode with an invalid format: expected an error, got nil")
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2020 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"encoding/json"
+	"net"
+	"time"
+)
+
+func init() {
+	Register("gelf", newGELFSink)
+}
+
+// gelfSink sends events as uncompressed GELF messages over UDP
+// to a Graylog input, e.g. "graylog.example.com:12201".
+type gelfSink struct {
+	conn *net.UDPConn
+	host string
+}
+
+// newGELFSink ignores format: GELF messages are JSON by spec,
+// so --format has no effect on this sink.
+func newGELFSink(url string, _ Format) (Sink, error) {
+	_, addr := splitSchemeAddr(url)
+	raddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialUDP("udp", nil, raddr)
+	if err != nil {
+		return nil, err
+	}
+
+	hostName, _, err := net.SplitHostPort(conn.LocalAddr().String())
+	if err != nil {
+		hostName = "kes"
+	}
+	return &gelfSink{conn: conn, host: hostName}, nil
+}
+
+// gelfMessage is the subset of the GELF payload spec
+// (https://go2docs.graylog.org/current/getting_in_log_data/gelf.html)
+// that the KES sinks populate.
+type gelfMessage struct {
+	Version      string  `json:"version"`
+	Host         string  `json:"host"`
+	ShortMessage string  `json:"short_message"`
+	Timestamp    float64 `json:"timestamp"`
+	Level        int     `json:"level"`
+
+	Identity string `json:"_identity,omitempty"`
+	Path     string `json:"_path,omitempty"`
+	Status   int    `json:"_status,omitempty"`
+}
+
+func (s *gelfSink) Write(e Event) error {
+	short := e.Message
+	if short == "" {
+		short = e.Identity + " " + e.Path
+	}
+
+	ts := e.Time
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	msg := gelfMessage{
+		Version:      "1.1",
+		Host:         s.host,
+		ShortMessage: short,
+		Timestamp:    float64(ts.UnixNano()) / 1e9,
+		Level:        gelfSeverity(e.Level),
+		Identity:     e.Identity,
+		Path:         e.Path,
+		Status:       e.Status,
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = s.conn.Write(payload)
+	return err
+}
+
+// gelfSeverity maps a Level to the syslog severity GELF expects.
+func gelfSeverity(l Level) int {
+	switch l {
+	case LevelDebug:
+		return 7
+	case LevelWarn:
+		return 4
+	case LevelError:
+		return 3
+	default:
+		return 6
+	}
+}
+
+func (s *gelfSink) Close() error { return s.conn.Close() }
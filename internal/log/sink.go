@@ -0,0 +1,140 @@
+// Copyright 2020 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+// Package log provides structured sinks for the KES audit
+// and error log streams consumed by the `kes log` commands.
+//
+// A Sink receives one Event at a time and is responsible for
+// encoding and delivering it - to a file, a syslog daemon, a
+// GELF/Graylog endpoint or an Elasticsearch cluster. Sinks are
+// looked up by name through the package-level registry and are
+// wrapped in a bounded, buffered writer so that a slow or stuck
+// sink cannot block the log stream the events are read from.
+package log
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is the minimum severity an Event must have in
+// order to be delivered to a Sink.
+type Level int
+
+// Supported log levels, ordered from most to least verbose.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// ParseLevel parses s into a Level. It accepts the values
+// "debug", "info", "warn" and "error", case-insensitively.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return LevelDebug, nil
+	case "info", "":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return 0, fmt.Errorf("log: invalid level %q", s)
+	}
+}
+
+// Event is a single audit or error log entry that has been
+// normalized into a common set of structured fields so that
+// it can be handled uniformly by any Sink.
+type Event struct {
+	Kind  string // "audit" or "error"
+	Level Level
+	Time  time.Time // when the event occurred; zero if unknown
+
+	Identity string // Audit only
+	Path     string // Audit only
+	Status   int    // Audit only
+
+	Message string // Error only
+
+	Fields map[string]interface{}
+}
+
+// Sink writes Events to some destination - a file, a remote
+// log collector, etc. Implementations must be safe to use
+// from multiple goroutines only if they don't rely on the
+// backpressure wrapper returned by Buffered, which already
+// serializes all calls to Write.
+type Sink interface {
+	// Write delivers the event to the sink. Delivery may be
+	// asynchronous: the Buffered wrapper and the Elasticsearch
+	// sink keep e, including e.Fields, after Write returns.
+	Write(e Event) error
+
+	// Close flushes any pending events and releases the
+	// resources held by the sink.
+	Close() error
+}
+
+// Factory creates a new Sink for the given target URL. The
+// meaning of url is sink-specific - a file path, a host:port,
+// or an HTTP(S) endpoint. format is the wire format requested
+// via --format; sinks whose wire format is fixed by the
+// protocol they speak (GELF, the Elasticsearch bulk API) are
+// free to ignore it.
+type Factory func(url string, format Format) (Sink, error)
+
+var (
+	mu       sync.RWMutex
+	registry = map[string]Factory{}
+)
+
+// Register adds a Sink factory under name to the registry so
+// that it becomes available as a `--sink=<name>` value. Register
+// panics if name is already registered - it is meant to be called
+// from init functions only.
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("log: sink %q already registered", name))
+	}
+	registry[name] = factory
+}
+
+// New creates the Sink registered under name, pointed at url,
+// encoding events with format, and wraps it in a bounded buffer
+// so that Write never blocks the caller on a slow sink.
+func New(name, url string, format Format) (Sink, error) {
+	mu.RLock()
+	factory, ok := registry[name]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("log: no such sink %q", name)
+	}
+
+	sink, err := factory(url, format)
+	if err != nil {
+		return nil, fmt.Errorf("log: failed to create %q sink: %v", name, err)
+	}
+	return Buffered(sink, 1024), nil
+}
+
+// Names returns the names of all registered sinks.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	return names
+}
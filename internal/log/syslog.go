@@ -0,0 +1,64 @@
+// Copyright 2020 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+//go:build !windows
+// +build !windows
+
+package log
+
+import (
+	"log/syslog"
+)
+
+func init() {
+	Register("syslog", newSyslogSink)
+}
+
+// syslogSink forwards events to a syslog daemon. An empty url
+// connects to the local syslog daemon; otherwise url is treated
+// as a "tcp://host:port" or "udp://host:port" address.
+type syslogSink struct {
+	writer *syslog.Writer
+	format Format
+}
+
+func newSyslogSink(url string, format Format) (Sink, error) {
+	var (
+		w   *syslog.Writer
+		err error
+	)
+	if url == "" {
+		w, err = syslog.New(syslog.LOG_INFO, "kes")
+	} else {
+		network, addr := splitSchemeAddr(url)
+		w, err = syslog.Dial(network, addr, syslog.LOG_INFO, "kes")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if format == "" {
+		format = FormatLogfmt
+	}
+	return &syslogSink{writer: w, format: format}, nil
+}
+
+func (s *syslogSink) Write(e Event) error {
+	line, err := s.format.Encode(e)
+	if err != nil {
+		return err
+	}
+
+	switch e.Level {
+	case LevelDebug:
+		return s.writer.Debug(string(line))
+	case LevelWarn:
+		return s.writer.Warning(string(line))
+	case LevelError:
+		return s.writer.Err(string(line))
+	default:
+		return s.writer.Info(string(line))
+	}
+}
+
+func (s *syslogSink) Close() error { return s.writer.Close() }
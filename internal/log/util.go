@@ -0,0 +1,17 @@
+// Copyright 2020 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package log
+
+import "strings"
+
+// splitSchemeAddr splits a "scheme://addr" sink URL into its
+// network and address parts, defaulting to "tcp" when no scheme
+// is present.
+func splitSchemeAddr(url string) (network, addr string) {
+	if i := strings.Index(url, "://"); i >= 0 {
+		return url[:i], url[i+3:]
+	}
+	return "tcp", url
+}
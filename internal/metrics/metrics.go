@@ -0,0 +1,153 @@
+// Copyright 2020 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+// Package metrics turns the KES audit/error log streams into
+// Prometheus/OpenMetrics series so that `kes log trace
+// --metrics-addr` can run as a sidecar and make any KES server
+// a first-class Prometheus target without server-side changes.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// DefaultDurationBuckets are used for kes_request_duration_seconds
+// unless the caller supplies its own via Config.DurationBuckets.
+var DefaultDurationBuckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Config controls cardinality limits and histogram resolution.
+// Zero values fall back to sane defaults.
+type Config struct {
+	DurationBuckets []float64
+	// TopK bounds how many distinct identity/path label values
+	// are tracked before the rest collapse into "other".
+	TopK int
+}
+
+// Collector maps audit/error log events onto a small set of
+// Prometheus series: request counts and latencies by identity,
+// path and status, an error counter, and stream health gauges.
+type Collector struct {
+	reg *prometheus.Registry
+
+	requestsTotal    *prometheus.CounterVec
+	requestDuration  *prometheus.HistogramVec
+	errorsTotal      prometheus.Counter
+	streamConnected  *prometheus.GaugeVec
+	streamReconnects *prometheus.CounterVec
+
+	identities *topKSet
+	paths      *topKSet
+}
+
+// New creates a Collector registered on a fresh, private
+// Prometheus registry - so a sidecar process never picks up the
+// Go runtime / process metrics the default global registry adds.
+func New(cfg Config) *Collector {
+	if len(cfg.DurationBuckets) == 0 {
+		cfg.DurationBuckets = DefaultDurationBuckets
+	}
+	if cfg.TopK <= 0 {
+		cfg.TopK = 20
+	}
+
+	c := &Collector{
+		reg: prometheus.NewRegistry(),
+		requestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "kes_requests_total",
+			Help: "Total number of KES API requests observed on the audit log.",
+		}, []string{"identity", "path", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "kes_request_duration_seconds",
+			Help:    "KES API request latency, as observed on the audit log.",
+			Buckets: cfg.DurationBuckets,
+		}, []string{"path"}),
+		errorsTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "kes_errors_total",
+			Help: "Total number of KES error log events observed.",
+		}),
+		streamConnected: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "kes_stream_connected",
+			Help: "Whether the log stream this sidecar reads from is currently connected (1) or not (0).",
+		}, []string{"type"}),
+		streamReconnects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "kes_stream_reconnects_total",
+			Help: "Total number of times the log stream this sidecar reads from had to reconnect.",
+		}, []string{"type"}),
+		identities: newTopKSet(cfg.TopK),
+		paths:      newTopKSet(cfg.TopK),
+	}
+
+	c.reg.MustRegister(c.requestsTotal, c.requestDuration, c.errorsTotal, c.streamConnected, c.streamReconnects)
+	return c
+}
+
+// ObserveAudit records one audit log event.
+func (c *Collector) ObserveAudit(identity, path string, status int, latency time.Duration) {
+	identity = c.identities.label(identity)
+	path = c.paths.label(path)
+
+	c.requestsTotal.WithLabelValues(identity, path, strconv.Itoa(status)).Inc()
+	c.requestDuration.WithLabelValues(path).Observe(latency.Seconds())
+}
+
+// ObserveError records one error log event.
+func (c *Collector) ObserveError() { c.errorsTotal.Inc() }
+
+// SetStreamConnected reports the connection state of the given
+// stream type ("audit" or "error").
+func (c *Collector) SetStreamConnected(streamType string, connected bool) {
+	v := 0.0
+	if connected {
+		v = 1.0
+	}
+	c.streamConnected.WithLabelValues(streamType).Set(v)
+}
+
+// IncReconnect records a reconnect of the given stream type.
+func (c *Collector) IncReconnect(streamType string) {
+	c.streamReconnects.WithLabelValues(streamType).Inc()
+}
+
+// Handler returns the /metrics HTTP handler for pull-based
+// scraping.
+func (c *Collector) Handler() http.Handler {
+	return promhttp.HandlerFor(c.reg, promhttp.HandlerOpts{})
+}
+
+// Serve starts an HTTP server exposing Handler at /metrics on
+// addr and blocks until it exits.
+func (c *Collector) Serve(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", c.Handler())
+	return http.ListenAndServe(addr, mux)
+}
+
+// PushLoop periodically pushes the current metrics to a
+// Prometheus Pushgateway at url under the given job name, until
+// ctx is canceled or a push fails; the first push error stops the
+// loop and is returned.
+func (c *Collector) PushLoop(ctx context.Context, url, job string, interval time.Duration) error {
+	pusher := push.New(url, job).Gatherer(c.reg)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := pusher.Push(); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2020 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import "sync"
+
+// topKSet bounds the number of distinct label values a metric
+// can accumulate. The first k values seen get their own label;
+// anything beyond that is folded into "other" so that a noisy or
+// malicious client can't blow up a series' cardinality.
+type topKSet struct {
+	k int
+
+	mu    sync.Mutex
+	known map[string]struct{}
+}
+
+func newTopKSet(k int) *topKSet {
+	return &topKSet{k: k, known: make(map[string]struct{}, k)}
+}
+
+// label returns v unchanged if it is already tracked or there is
+// still room for it, and "other" otherwise.
+func (s *topKSet) label(v string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.known[v]; ok {
+		return v
+	}
+	if len(s.known) < s.k {
+		s.known[v] = struct{}{}
+		return v
+	}
+	return "other"
+}
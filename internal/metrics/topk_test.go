@@ -0,0 +1,27 @@
+// Copyright 2020 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import "testing"
+
+func TestTopKSetLabel(t *testing.T) {
+	s := newTopKSet(2)
+
+	if got := s.label("a"); got != "a" {
+		t.Fatalf("label(%q) = %q, want %q", "a", got, "a")
+	}
+	if got := s.label("b"); got != "b" {
+		t.Fatalf("label(%q) = %q, want %q", "b", got, "b")
+	}
+
+	// k is exhausted now; a new value collapses into "other" ...
+	if got := s.label("c"); got != "other" {
+		t.Fatalf("label(%q) = %q, want %q", "c", got, "other")
+	}
+	// ... but values already tracked keep their own label.
+	if got := s.label("a"); got != "a" {
+		t.Fatalf("label(%q) = %q, want %q", "a", got, "a")
+	}
+}
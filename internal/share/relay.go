@@ -0,0 +1,39 @@
+// Copyright 2020 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package share
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/hashicorp/yamux"
+)
+
+// ServeRelay dials relayAddr and serves s's HTTP handler over a
+// single yamux-multiplexed connection instead of a local
+// listener, so that operators behind NAT can still share a
+// trace session through a public relay. Each accepted yamux
+// stream is treated as one client connection.
+func (s *Server) ServeRelay(relayAddr string, tlsConfig *tls.Config) error {
+	var conn net.Conn
+	var err error
+	if tlsConfig != nil {
+		conn, err = tls.Dial("tcp", relayAddr, tlsConfig)
+	} else {
+		conn, err = net.Dial("tcp", relayAddr)
+	}
+	if err != nil {
+		return fmt.Errorf("share: failed to dial relay %s: %v", relayAddr, err)
+	}
+
+	session, err := yamux.Client(conn, nil)
+	if err != nil {
+		return fmt.Errorf("share: failed to establish relay session: %v", err)
+	}
+
+	return http.Serve(session, s.http.Handler)
+}
@@ -0,0 +1,296 @@
+// Copyright 2020 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+// Package share implements the read-only terminal sharing mode
+// behind `kes log trace --share`: a small embedded HTTP server
+// that multiplexes a live trace session to any number of
+// browser viewers over a websocket. Clients that send
+// "Accept: application/x-ndjson" get the raw JSON events, one per
+// websocket message, instead of rendered frames; there is no
+// plain-HTTP (non-websocket) stream.
+package share
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Config configures a Server.
+type Config struct {
+	Addr        string        // e.g. ":7474"
+	Token       string        // shared per-session token; generated if empty
+	IdleTimeout time.Duration // viewers are dropped after this much inactivity
+	TLSConfig   *tls.Config   // optional; nil serves plain HTTP
+}
+
+// Server multiplexes a single trace session to many read-only
+// viewers. Viewers connect over a websocket and receive every
+// frame broadcast with Frame, or - if they send an
+// "Accept: application/x-ndjson" request - the raw JSON events
+// passed to NDJSON instead of the rendered terminal UI.
+//
+// Server never forwards viewer input back into the CLI: the
+// websocket handler only ever writes to viewers, never reads
+// application data from them.
+type Server struct {
+	cfg    Config
+	http   *http.Server
+	upgrad websocket.Upgrader
+
+	mu       sync.Mutex
+	viewers  map[*viewer]struct{}
+	sessions map[string]time.Time // token -> last seen
+}
+
+type viewer struct {
+	conn   *websocket.Conn
+	ndjson bool
+	send   chan []byte
+
+	// lastActive is a UnixNano timestamp, updated whenever the
+	// viewer's connection shows signs of life (connect, or a
+	// pong replying to our keepalive ping), and read by
+	// reapIdleSessions to find stale viewers. Accessed
+	// atomically since it's touched from the read goroutine and
+	// read from the reaper goroutine.
+	lastActive int64
+}
+
+func (v *viewer) touch() { atomic.StoreInt64(&v.lastActive, time.Now().UnixNano()) }
+
+func (v *viewer) idleFor() time.Duration {
+	return time.Since(time.Unix(0, atomic.LoadInt64(&v.lastActive)))
+}
+
+// New creates a Server. If cfg.Token is empty a random 128-bit
+// token is generated and can be read back with Server.Token.
+func New(cfg Config) *Server {
+	if cfg.Token == "" {
+		cfg.Token = randomToken()
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = 30 * time.Minute
+	}
+
+	s := &Server{
+		cfg:      cfg,
+		viewers:  make(map[*viewer]struct{}),
+		sessions: make(map[string]time.Time),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleIndex)
+	mux.HandleFunc("/ws", s.handleWS)
+	s.http = &http.Server{
+		Addr:      cfg.Addr,
+		Handler:   mux,
+		TLSConfig: cfg.TLSConfig,
+	}
+	go s.reapIdleSessions()
+	return s
+}
+
+// Token returns the per-session token viewers must present as
+// either a "token" query parameter or "Authorization: Bearer"
+// header.
+func (s *Server) Token() string { return s.cfg.Token }
+
+// Serve starts accepting connections and blocks until the
+// server is closed. Callers typically run it in a goroutine.
+func (s *Server) Serve() error {
+	if s.cfg.TLSConfig != nil {
+		return s.http.ListenAndServeTLS("", "")
+	}
+	return s.http.ListenAndServe()
+}
+
+// Close shuts down the server and disconnects all viewers.
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := s.http.Shutdown(ctx)
+
+	// Shutdown does not close hijacked (websocket) connections.
+	s.mu.Lock()
+	viewers := make([]*viewer, 0, len(s.viewers))
+	for v := range s.viewers {
+		viewers = append(viewers, v)
+	}
+	s.mu.Unlock()
+	for _, v := range viewers {
+		s.disconnect(v)
+	}
+	return err
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	if token != s.cfg.Token {
+		return false
+	}
+	s.mu.Lock()
+	s.sessions[token] = time.Now()
+	s.mu.Unlock()
+	return true
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		http.Error(w, "invalid or missing token", http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, indexHTML)
+}
+
+func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		http.Error(w, "invalid or missing token", http.StatusUnauthorized)
+		return
+	}
+
+	// Non-browser clients ask for the raw event stream instead
+	// of the rendered terminal frames.
+	ndjson := strings.Contains(r.Header.Get("Accept"), "application/x-ndjson")
+
+	conn, err := s.upgrad.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	v := &viewer{conn: conn, ndjson: ndjson, send: make(chan []byte, 64)}
+	v.touch()
+	conn.SetPongHandler(func(string) error { v.touch(); return nil })
+
+	s.mu.Lock()
+	s.viewers[v] = struct{}{}
+	s.mu.Unlock()
+
+	go s.writeLoop(v)
+	s.discardReads(v) // viewer input is never applied - read-only session
+}
+
+// discardReads blocks reading (and dropping) any data a viewer
+// sends, which both keeps the websocket connection alive and
+// guarantees viewer input can never reach the traced CLI.
+func (s *Server) discardReads(v *viewer) {
+	defer s.disconnect(v)
+	for {
+		if _, _, err := v.conn.ReadMessage(); err != nil {
+			return
+		}
+		v.touch()
+	}
+}
+
+func (s *Server) writeLoop(v *viewer) {
+	for frame := range v.send {
+		if err := v.conn.WriteMessage(websocket.TextMessage, frame); err != nil {
+			s.disconnect(v)
+			return
+		}
+	}
+}
+
+func (s *Server) disconnect(v *viewer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.viewers[v]; ok {
+		delete(s.viewers, v)
+		close(v.send)
+		v.conn.Close()
+	}
+}
+
+// Frame broadcasts a rendered terminal frame to every connected
+// browser viewer that did not ask for the NDJSON fallback.
+func (s *Server) Frame(frame []byte) { s.broadcast(frame, false) }
+
+// NDJSON broadcasts one raw JSON event line to every viewer
+// that requested the "application/x-ndjson" fallback.
+func (s *Server) NDJSON(line []byte) { s.broadcast(line, true) }
+
+func (s *Server) broadcast(payload []byte, ndjson bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for v := range s.viewers {
+		if v.ndjson != ndjson {
+			continue
+		}
+		select {
+		case v.send <- payload:
+		default: // slow viewer - drop the frame rather than block the trace
+		}
+	}
+}
+
+// reapIdleSessions drops expired re-authentication tokens and,
+// separately, disconnects viewers whose own connection has gone
+// idle - an already-upgraded websocket keeps receiving frames
+// forever unless something actively closes it, so expiring the
+// token alone isn't enough to enforce --share-idle-timeout.
+func (s *Server) reapIdleSessions() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.mu.Lock()
+		for token, lastSeen := range s.sessions {
+			if time.Since(lastSeen) > s.cfg.IdleTimeout {
+				delete(s.sessions, token)
+			}
+		}
+		viewers := make([]*viewer, 0, len(s.viewers))
+		for v := range s.viewers {
+			viewers = append(viewers, v)
+		}
+		s.mu.Unlock()
+
+		for _, v := range viewers {
+			if v.idleFor() > s.cfg.IdleTimeout {
+				s.disconnect(v)
+				continue
+			}
+			_ = v.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(5*time.Second))
+		}
+	}
+}
+
+func randomToken() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+const indexHTML = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>kes log trace (read-only)</title>
+  <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/xterm/css/xterm.css" />
+  <script src="https://cdn.jsdelivr.net/npm/xterm/lib/xterm.js"></script>
+  <style>body{margin:0;background:#000}</style>
+</head>
+<body>
+  <div id="term"></div>
+  <script>
+    const term = new Terminal({ convertEol: true, disableStdin: true });
+    term.open(document.getElementById('term'));
+
+    const url = new URL(location.href);
+    const ws = new WebSocket((url.protocol === 'https:' ? 'wss://' : 'ws://') + url.host + '/ws?token=' + url.searchParams.get('token'));
+    ws.onmessage = (ev) => term.write(ev.data);
+  </script>
+</body>
+</html>`
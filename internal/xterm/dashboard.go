@@ -0,0 +1,457 @@
+// Copyright 2020 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package xterm
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	ui "github.com/gizak/termui/v3"
+	"github.com/gizak/termui/v3/widgets"
+)
+
+// Row is a single event shown in a Dashboard - the rendered
+// table columns plus the raw value that produced them, which is
+// what gets pretty-printed when the row is drilled into.
+type Row struct {
+	Columns []string
+	Raw     interface{}
+	Status  int
+	Latency time.Duration
+}
+
+// DashboardConfig controls the size of the scrollback ring
+// buffer and the width of the rolling stats window. Both are
+// meant to be wired up to CLI flags by callers.
+type DashboardConfig struct {
+	Headers    []string
+	RingSize   int           // number of rows retained for scrollback
+	Window     time.Duration // width of the rolling latency-quantile window
+	NumBuckets int           // number of buckets the window is split into
+}
+
+// Dashboard is a multi-pane termui view over a live event stream:
+// a scrollable, filterable table of events, a detail pane showing
+// the raw JSON of the selected row, and a stats pane with the
+// request count and error rate since start, latency quantiles over
+// the rolling window and a top-N identity breakdown. Request count
+// and error rate are cumulative, not per-window rates.
+//
+// Dashboard owns no network I/O and has no event loop of its own -
+// callers feed it rows with AddRow and drive Rotate, FilterKey,
+// Select, Resize, Pause and Resume from their own termui loop.
+//
+// AddRow is meant to be called from the goroutine reading the
+// event stream while Rotate, FilterKey, Select, Resize, Pause
+// and Resume run off termui's ticker/PollEvents goroutine, so
+// every exported method takes mu before touching Dashboard
+// state - none of it is otherwise safe for concurrent access.
+type Dashboard struct {
+	mu sync.Mutex
+
+	table  *widgets.Table
+	detail *widgets.Paragraph
+	stats  *widgets.Paragraph
+	spark  *widgets.SparklineGroup
+	filter *widgets.Paragraph
+	grid   *ui.Grid
+
+	headers []string
+	ring    []Row
+	head    int
+	count   int
+
+	paused   bool
+	selected int
+
+	identityRe *regexp.Regexp
+	pathRe     *regexp.Regexp
+	minStatus  int
+	maxStatus  int
+
+	quantiles  *QuantileWindow
+	window     time.Duration
+	requests   int
+	errors     int
+	identities map[string]int
+
+	editingFilter bool
+	filterInput   string
+}
+
+// NewDashboard creates a Dashboard ready to be fed. cfg.Headers
+// is the set of table columns; cfg.RingSize and cfg.Window
+// default to 10000 rows and a 60s/60-bucket quantile window.
+func NewDashboard(cfg DashboardConfig) *Dashboard {
+	if cfg.RingSize <= 0 {
+		cfg.RingSize = 10000
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 60 * time.Second
+	}
+	if cfg.NumBuckets <= 0 {
+		cfg.NumBuckets = 60
+	}
+
+	d := &Dashboard{
+		headers:    cfg.Headers,
+		ring:       make([]Row, cfg.RingSize),
+		maxStatus:  999,
+		quantiles:  NewQuantileWindow(cfg.Window, cfg.NumBuckets),
+		window:     cfg.Window,
+		identities: make(map[string]int),
+	}
+
+	d.table = widgets.NewTable()
+	d.table.Title = "Events"
+	d.table.Rows = [][]string{cfg.Headers}
+	d.table.RowSeparator = false
+
+	d.detail = widgets.NewParagraph()
+	d.detail.Title = "Selected event"
+
+	d.stats = widgets.NewParagraph()
+	d.stats.Title = "Stats"
+
+	d.spark = widgets.NewSparklineGroup(widgets.NewSparkline())
+	d.spark.Title = "Error rate"
+
+	d.filter = widgets.NewParagraph()
+	d.filter.Title = "Filter (/ identity | path | status>=N, Enter to apply, Esc to clear)"
+	d.filter.Text = "(none)"
+
+	d.grid = ui.NewGrid()
+	d.grid.Set(
+		ui.NewRow(0.08, ui.NewCol(1.0, d.filter)),
+		ui.NewRow(0.52, ui.NewCol(1.0, d.table)),
+		ui.NewRow(0.2, ui.NewCol(1.0, d.detail)),
+		ui.NewRow(0.2,
+			ui.NewCol(0.6, d.stats),
+			ui.NewCol(0.4, d.spark),
+		),
+	)
+	return d
+}
+
+// Resize lays the dashboard out within a w x h terminal.
+func (d *Dashboard) Resize(w, h int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.grid.SetRect(0, 0, w, h)
+}
+
+// AddRow appends a new event to the ring buffer and, unless the
+// dashboard is paused, refreshes the visible table and stats
+// panes. Stats count every row; the table shows only rows that
+// pass the current filter.
+func (d *Dashboard) AddRow(row Row) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.ring[d.head] = row
+	d.head = (d.head + 1) % len(d.ring)
+	if d.count < len(d.ring) {
+		d.count++
+	}
+
+	d.requests++
+	if row.Status >= 400 {
+		d.errors++
+	}
+	if len(row.Columns) > 1 {
+		d.identities[row.Columns[1]]++
+	}
+	if row.Latency > 0 {
+		d.quantiles.Insert(float64(row.Latency.Microseconds()))
+	}
+
+	if d.paused {
+		return
+	}
+	d.refreshTable()
+	d.refreshStats()
+	ui.Render(d.grid)
+}
+
+// Rotate advances the rolling latency-quantile window by one tick.
+// Callers should invoke it from a ticker running at
+// d.quantiles.Tick().
+func (d *Dashboard) Rotate() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.quantiles.Rotate()
+	if !d.paused {
+		d.refreshStats()
+		ui.Render(d.grid)
+	}
+}
+
+// RotateInterval returns how often Rotate should be called to
+// keep the rolling stats window accurate.
+func (d *Dashboard) RotateInterval() time.Duration { return d.quantiles.Tick() }
+
+// Paused reports whether the dashboard is currently frozen.
+func (d *Dashboard) Paused() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.paused
+}
+
+// Pause freezes the table and stats panes so an operator can
+// inspect a snapshot while events keep accumulating in the ring
+// buffer in the background.
+func (d *Dashboard) Pause() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.paused = true
+}
+
+// Resume un-freezes the dashboard and immediately redraws it
+// with everything that accumulated while paused.
+func (d *Dashboard) Resume() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.paused = false
+	d.refreshTable()
+	d.refreshStats()
+	ui.Render(d.grid)
+}
+
+// matches reports whether row passes the current identity/path
+// regex and status-range filters.
+func (d *Dashboard) matches(row Row) bool {
+	if d.identityRe != nil && len(row.Columns) > 1 && !d.identityRe.MatchString(row.Columns[1]) {
+		return false
+	}
+	if d.pathRe != nil && len(row.Columns) > 3 && !d.pathRe.MatchString(row.Columns[3]) {
+		return false
+	}
+	if row.Status != 0 && (row.Status < d.minStatus || row.Status > d.maxStatus) {
+		return false
+	}
+	return true
+}
+
+func (d *Dashboard) visibleRows() []Row {
+	rows := make([]Row, 0, d.count)
+	for i := 0; i < d.count; i++ {
+		idx := (d.head - 1 - i + len(d.ring)) % len(d.ring)
+		row := d.ring[idx]
+		if d.matches(row) {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+func (d *Dashboard) refreshTable() {
+	rows := d.visibleRows()
+	table := make([][]string, 0, len(rows)+1)
+	table = append(table, d.headers)
+	for _, r := range rows {
+		table = append(table, r.Columns)
+	}
+	d.table.Rows = table
+}
+
+func (d *Dashboard) refreshStats() {
+	errRate := 0.0
+	if d.requests > 0 {
+		errRate = float64(d.errors) / float64(d.requests) * 100
+	}
+
+	type kv struct {
+		name  string
+		count int
+	}
+	top := make([]kv, 0, len(d.identities))
+	for k, v := range d.identities {
+		top = append(top, kv{k, v})
+	}
+	for i := 0; i < len(top); i++ {
+		for j := i + 1; j < len(top); j++ {
+			if top[j].count > top[i].count {
+				top[i], top[j] = top[j], top[i]
+			}
+		}
+	}
+	if len(top) > 5 {
+		top = top[:5]
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "requests: %d  error-rate: %.1f%%\n", d.requests, errRate)
+	fmt.Fprintf(&sb, "p50: %s  p95: %s  p99: %s\n",
+		formatMicros(d.quantiles.Quantile(0.50)),
+		formatMicros(d.quantiles.Quantile(0.95)),
+		formatMicros(d.quantiles.Quantile(0.99)),
+	)
+	sb.WriteString("top identities: ")
+	for i, e := range top {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "%s(%d)", e.name, e.count)
+	}
+	d.stats.Text = sb.String()
+
+	if d.requests > 0 {
+		d.spark.Sparklines[0].Data = append(d.spark.Sparklines[0].Data, errRate)
+		if len(d.spark.Sparklines[0].Data) > 120 {
+			d.spark.Sparklines[0].Data = d.spark.Sparklines[0].Data[1:]
+		}
+	}
+}
+
+func formatMicros(us float64) string {
+	d := time.Duration(us) * time.Microsecond
+	return d.Truncate(10 * time.Microsecond).String()
+}
+
+// Select moves the detail-pane selection up (delta<0) or down
+// (delta>0) within the currently visible rows and refreshes the
+// detail pane to show the newly selected row's raw JSON.
+func (d *Dashboard) Select(delta int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	rows := d.visibleRows()
+	if len(rows) == 0 {
+		return
+	}
+	d.selected += delta
+	if d.selected < 0 {
+		d.selected = 0
+	}
+	if d.selected >= len(rows) {
+		d.selected = len(rows) - 1
+	}
+	d.showDetail(rows[d.selected])
+	ui.Render(d.grid)
+}
+
+func (d *Dashboard) showDetail(row Row) {
+	raw, err := json.MarshalIndent(row.Raw, "", "  ")
+	if err != nil {
+		d.detail.Text = err.Error()
+		return
+	}
+	d.detail.Text = string(raw)
+}
+
+// BeginFilterEdit opens the filter bar for editing.
+func (d *Dashboard) BeginFilterEdit() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.editingFilter = true
+	d.filterInput = ""
+	d.filter.Text = "/"
+	ui.Render(d.grid)
+}
+
+// FilterKey feeds a single key event into the filter bar while
+// it is being edited; Enter applies the filter, Escape clears it.
+// It returns true if the key was consumed by the filter editor.
+func (d *Dashboard) FilterKey(key string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.editingFilter {
+		return false
+	}
+	switch key {
+	case "<Enter>":
+		d.applyFilter(d.filterInput)
+		d.editingFilter = false
+	case "<Escape>":
+		d.editingFilter = false
+		d.applyFilter("")
+	case "<Backspace>":
+		if n := len(d.filterInput); n > 0 {
+			d.filterInput = d.filterInput[:n-1]
+		}
+		d.filter.Text = "/" + d.filterInput
+	default:
+		if len(key) == 1 {
+			d.filterInput += key
+			d.filter.Text = "/" + d.filterInput
+		}
+	}
+	ui.Render(d.grid)
+	return true
+}
+
+// ApplyFilter parses and installs a filter expression of the
+// form "identity=<regex>", "path=<regex>" or "status>=<code>",
+// combined with whitespace, e.g. "identity=admin.* status>=400".
+func (d *Dashboard) ApplyFilter(expr string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.applyFilter(expr)
+}
+
+// applyFilter is ApplyFilter's body, callable from FilterKey
+// which already holds d.mu.
+func (d *Dashboard) applyFilter(expr string) error {
+	d.identityRe, d.pathRe = nil, nil
+	d.minStatus, d.maxStatus = 0, 999
+
+	for _, term := range strings.Fields(expr) {
+		switch {
+		case strings.HasPrefix(term, "identity="):
+			re, err := regexp.Compile(strings.TrimPrefix(term, "identity="))
+			if err != nil {
+				return err
+			}
+			d.identityRe = re
+		case strings.HasPrefix(term, "path="):
+			re, err := regexp.Compile(strings.TrimPrefix(term, "path="))
+			if err != nil {
+				return err
+			}
+			d.pathRe = re
+		case strings.HasPrefix(term, "status>="):
+			n, err := strconv.Atoi(strings.TrimPrefix(term, "status>="))
+			if err != nil {
+				return err
+			}
+			d.minStatus = n
+		case strings.HasPrefix(term, "status<="):
+			n, err := strconv.Atoi(strings.TrimPrefix(term, "status<="))
+			if err != nil {
+				return err
+			}
+			d.maxStatus = n
+		}
+	}
+
+	if expr == "" {
+		d.filter.Text = "(none)"
+	} else {
+		d.filter.Text = expr
+	}
+	d.refreshTable()
+	return nil
+}
+
+// Grid returns the root termui drawable so callers can Render
+// and resize it as part of their own event loop.
+func (d *Dashboard) Grid() *ui.Grid { return d.grid }
+
+// EditingFilter reports whether the filter bar currently has
+// keyboard focus.
+func (d *Dashboard) EditingFilter() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.editingFilter
+}
@@ -0,0 +1,155 @@
+// Copyright 2020 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package xterm
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// centroid is a single (mean, weight) pair as used by
+// t-digest-style quantile sketches: a cluster of nearby samples
+// represented by their mean and the number of samples it absorbed.
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+// compression bounds the number of centroids a single bucket is
+// allowed to hold before nearby centroids are merged together.
+// Smaller values trade accuracy for memory; 0.01 keeps rank error
+// within about 1% which is more than sufficient for a live UI.
+const compression = 0.01
+
+// bucket accumulates centroids for one time slice of the sliding
+// quantile window.
+type bucket struct {
+	centroids []centroid
+	count     float64
+}
+
+func (b *bucket) insert(v float64) {
+	b.count++
+	for i := range b.centroids {
+		if b.centroids[i].mean == v {
+			b.centroids[i].weight++
+			return
+		}
+	}
+	b.centroids = append(b.centroids, centroid{mean: v, weight: 1})
+	if len(b.centroids) > int(1/compression) {
+		b.compress()
+	}
+}
+
+// compress sorts the centroids by mean and merges adjacent pairs
+// until the centroid count is back under the 1/compression budget.
+func (b *bucket) compress() {
+	sort.Slice(b.centroids, func(i, j int) bool { return b.centroids[i].mean < b.centroids[j].mean })
+
+	limit := int(1 / compression)
+	for len(b.centroids) > limit {
+		merged := make([]centroid, 0, len(b.centroids)/2+1)
+		for i := 0; i < len(b.centroids); i += 2 {
+			if i+1 == len(b.centroids) {
+				merged = append(merged, b.centroids[i])
+				continue
+			}
+			a, c := b.centroids[i], b.centroids[i+1]
+			w := a.weight + c.weight
+			merged = append(merged, centroid{
+				mean:   (a.mean*a.weight + c.mean*c.weight) / w,
+				weight: w,
+			})
+		}
+		b.centroids = merged
+	}
+}
+
+// QuantileWindow computes approximate p50/p95/p99-style latency
+// quantiles over a sliding window of recent samples, using a
+// small compressed sketch per time bucket so memory stays bounded
+// no matter how many samples arrive.
+//
+// Buckets rotate on a fixed interval (one "tick"); the window is
+// the last numBuckets ticks. A quantile query merges every live
+// bucket's centroids into one sorted list and returns the mean of
+// the first centroid whose cumulative weight reaches the target
+// rank; it does not interpolate between neighbouring centroids.
+// This costs O(1/compression) per bucket regardless of how many
+// raw samples fed into it.
+type QuantileWindow struct {
+	tick       time.Duration
+	numBuckets int
+
+	mu      sync.Mutex
+	buckets []bucket
+	head    int
+}
+
+// NewQuantileWindow returns a QuantileWindow covering window,
+// split into numBuckets equally sized ticks (e.g. a 60s window
+// with 60 buckets rotates once per second).
+func NewQuantileWindow(window time.Duration, numBuckets int) *QuantileWindow {
+	if numBuckets < 1 {
+		numBuckets = 1
+	}
+	return &QuantileWindow{
+		tick:       window / time.Duration(numBuckets),
+		numBuckets: numBuckets,
+		buckets:    make([]bucket, numBuckets),
+	}
+}
+
+// Insert adds a sample to the current bucket.
+func (w *QuantileWindow) Insert(v float64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buckets[w.head].insert(v)
+}
+
+// Rotate advances to the next bucket, discarding the oldest one.
+// The caller is expected to call Rotate once per tick, e.g. from
+// a time.Ticker running at the window's tick interval.
+func (w *QuantileWindow) Rotate() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.head = (w.head + 1) % w.numBuckets
+	w.buckets[w.head] = bucket{}
+}
+
+// Tick returns the bucket rotation interval, for callers driving
+// Rotate from their own ticker.
+func (w *QuantileWindow) Tick() time.Duration { return w.tick }
+
+// Quantile returns the approximate value at rank q (0 <= q <= 1)
+// over all samples currently held in the window: the mean of the
+// first centroid whose cumulative weight reaches q times the total.
+func (w *QuantileWindow) Quantile(q float64) float64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var all []centroid
+	var total float64
+	for _, b := range w.buckets {
+		all = append(all, b.centroids...)
+		total += b.count
+	}
+	if total == 0 {
+		return 0
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+
+	target := q * total
+	var cum float64
+	for _, c := range all {
+		cum += c.weight
+		if cum >= target {
+			return c.mean
+		}
+	}
+	return all[len(all)-1].mean
+}
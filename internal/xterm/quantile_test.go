@@ -0,0 +1,49 @@
+// Copyright 2020 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package xterm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuantileWindowEmpty(t *testing.T) {
+	w := NewQuantileWindow(60*time.Second, 60)
+	if q := w.Quantile(0.5); q != 0 {
+		t.Fatalf("Quantile(0.5) on an empty window = %v, want 0", q)
+	}
+}
+
+func TestQuantileWindowApproximatesRank(t *testing.T) {
+	w := NewQuantileWindow(60*time.Second, 60)
+	for i := 1; i <= 100; i++ {
+		w.Insert(float64(i))
+	}
+
+	if p50 := w.Quantile(0.50); p50 != 50 {
+		t.Fatalf("Quantile(0.50) = %v, want 50", p50)
+	}
+	if p99 := w.Quantile(0.99); p99 != 99 {
+		t.Fatalf("Quantile(0.99) = %v, want 99", p99)
+	}
+	if max := w.Quantile(1); max != 100 {
+		t.Fatalf("Quantile(1) = %v, want 100", max)
+	}
+}
+
+func TestQuantileWindowRotateDropsOldBuckets(t *testing.T) {
+	w := NewQuantileWindow(2*time.Second, 2)
+	w.Insert(10)
+
+	w.Rotate() // advances into the second, still-empty bucket
+	if q := w.Quantile(0.5); q != 10 {
+		t.Fatalf("Quantile(0.5) after one Rotate = %v, want 10 (sample still in window)", q)
+	}
+
+	w.Rotate() // wraps back around, discarding the bucket holding our sample
+	if q := w.Quantile(0.5); q != 0 {
+		t.Fatalf("Quantile(0.5) after wrapping past the window = %v, want 0", q)
+	}
+}